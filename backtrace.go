@@ -0,0 +1,81 @@
+// Copyright 2016 The kingshard Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package golog
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+// backtraceSpec is one parsed "file:line" entry from SetBacktraceAt.
+type backtraceSpec struct {
+	file string
+	line int
+}
+
+// backtraceAt holds the current []backtraceSpec behind an atomic
+// pointer so Output's hot path never takes a lock to check it.
+var backtraceAt atomic.Value
+
+func init() {
+	backtraceAt.Store([]backtraceSpec{})
+}
+
+// SetBacktraceAt sets a comma-separated list of "file:line" locations,
+// e.g. "db.go:42,router.go:117". When Output's caller matches one of
+// them, the result of Stack(0) is appended to that log line.
+func SetBacktraceAt(spec string) error {
+	var specs []backtraceSpec
+
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		idx := strings.LastIndex(part, ":")
+		if idx < 0 {
+			return fmt.Errorf("golog: invalid backtrace location %q", part)
+		}
+
+		line, err := strconv.Atoi(part[idx+1:])
+		if err != nil {
+			return fmt.Errorf("golog: invalid backtrace line in %q: %v", part, err)
+		}
+
+		specs = append(specs, backtraceSpec{file: part[:idx], line: line})
+	}
+
+	backtraceAt.Store(specs)
+	return nil
+}
+
+// shouldBacktrace reports whether file:line matches a location set by
+// SetBacktraceAt. file is matched on its trailing path component only.
+func shouldBacktrace(file string, line int) bool {
+	base := file
+	if i := strings.LastIndexByte(file, '/'); i >= 0 {
+		base = file[i+1:]
+	}
+
+	for _, s := range backtraceAt.Load().([]backtraceSpec) {
+		if s.line == line && s.file == base {
+			return true
+		}
+	}
+	return false
+}
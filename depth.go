@@ -0,0 +1,66 @@
+// Copyright 2016 The kingshard Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package golog
+
+import "fmt"
+
+// InfoDepth is like Info but reports the caller depth frames above its
+// own, so a wrapper library (an HTTP middleware, a DB driver adapter,
+// the Logger.Write bridge) can make Output attribute the line
+// to its caller instead of to itself.
+func InfoDepth(depth int, module string, method string, msg string, args ...interface{}) {
+	outputDepth(LevelInfo, depth, module, method, msg, args...)
+}
+
+// InfoDepthf is InfoDepth with msg always treated as a printf format.
+func InfoDepthf(depth int, module string, method string, format string, args ...interface{}) {
+	outputDepthf(LevelInfo, depth, module, method, fmt.Sprintf(format, args...))
+}
+
+// WarnDepth is like Warn but reports the caller depth frames above its
+// own; see InfoDepth.
+func WarnDepth(depth int, module string, method string, msg string, args ...interface{}) {
+	outputDepth(LevelWarn, depth, module, method, msg, args...)
+}
+
+// WarnDepthf is WarnDepth with msg always treated as a printf format.
+func WarnDepthf(depth int, module string, method string, format string, args ...interface{}) {
+	outputDepthf(LevelWarn, depth, module, method, fmt.Sprintf(format, args...))
+}
+
+// ErrorDepth is like Error but reports the caller depth frames above
+// its own; see InfoDepth.
+func ErrorDepth(depth int, module string, method string, msg string, args ...interface{}) {
+	outputDepth(LevelError, depth, module, method, msg, args...)
+}
+
+// ErrorDepthf is ErrorDepth with msg always treated as a printf format.
+func ErrorDepthf(depth int, module string, method string, format string, args ...interface{}) {
+	outputDepthf(LevelError, depth, module, method, fmt.Sprintf(format, args...))
+}
+
+// FatalDepth is like Fatal — it dumps every goroutine's stack, flushes
+// every sink, and exits the process with status 255 — but reports the
+// caller depth frames above its own; see InfoDepth.
+func FatalDepth(depth int, module string, method string, msg string, args ...interface{}) {
+	outputDepth(LevelFatal, depth, module, method, msg, args...)
+	haltAndExit(255, true)
+}
+
+// FatalDepthf is FatalDepth with msg always treated as a printf format.
+func FatalDepthf(depth int, module string, method string, format string, args ...interface{}) {
+	outputDepthf(LevelFatal, depth, module, method, fmt.Sprintf(format, args...))
+	haltAndExit(255, true)
+}
@@ -0,0 +1,62 @@
+// Copyright 2016 The kingshard Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package golog
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+)
+
+// Exit logs msg, flushes every sink, and exits the process with status
+// 1. Unlike Fatal it does not dump goroutine stacks, since reaching
+// Exit isn't itself a bug — it's a controlled shutdown.
+func Exit(module string, method string, msg string, args ...interface{}) {
+	output(LevelFatal, module, method, msg, args...)
+	haltAndExit(1, false)
+}
+
+// Exitf is Exit with msg always treated as a printf format. It routes
+// through outputDepthf rather than output, since the format has
+// already been rendered here and must reach the sink verbatim; see
+// Verbose.Infof for why going back through output's '%'-sniffing would
+// corrupt a literal '%' left in the result.
+func Exitf(module string, method string, format string, args ...interface{}) {
+	outputDepthf(LevelFatal, 0, module, method, fmt.Sprintf(format, args...))
+	haltAndExit(1, false)
+}
+
+// haltAndExit flushes sysLogger synchronously, then exits the process
+// with code. If dumpStacks, it first logs the current goroutine's
+// stack (via Stack) and every other goroutine's stack (via
+// runtime.Stack) at FATAL level.
+func haltAndExit(code int, dumpStacks bool) {
+	if dumpStacks {
+		buf := make([]byte, 1<<20)
+		n := runtime.Stack(buf, true)
+
+		content := string(Stack(0)) + string(buf[:n])
+
+		// Stack traces routinely contain literal '%'s (format strings,
+		// comments, SQL, URLs); outputLiteral delivers content verbatim
+		// instead of Output's unconditional Sprintf(content), which
+		// would otherwise corrupt them.
+		sysLogger.outputLiteral(3, LevelFatal, "golog", "stack", content)
+	}
+
+	sysLogger.Close()
+
+	os.Exit(code)
+}
@@ -0,0 +1,134 @@
+// Copyright 2016 The kingshard Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package golog
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+type fieldType int
+
+const (
+	fieldString fieldType = iota
+	fieldInt
+	fieldDuration
+	fieldAny
+)
+
+// Field is a single structured key/value built by String, Int, Err,
+// Duration and friends, rather than constructed directly, so Infow and
+// With never box a value through interface{} unless there's truly no
+// cheaper representation.
+type Field struct {
+	Key   string
+	Type  fieldType
+	Int   int64
+	Str   string
+	Iface interface{}
+}
+
+// String builds a string-valued Field.
+func String(key, value string) Field {
+	return Field{Key: key, Type: fieldString, Str: value}
+}
+
+// Int builds an int-valued Field.
+func Int(key string, value int) Field {
+	return Field{Key: key, Type: fieldInt, Int: int64(value)}
+}
+
+// Duration builds a Field holding a time.Duration.
+func Duration(key string, value time.Duration) Field {
+	return Field{Key: key, Type: fieldDuration, Int: int64(value)}
+}
+
+// Any builds a Field from an arbitrary value, for cases the typed
+// helpers don't cover.
+func Any(key string, value interface{}) Field {
+	return Field{Key: key, Type: fieldAny, Iface: value}
+}
+
+// Err builds a Field named "error" from err. If err is nil the field
+// holds an empty string, so Err(err) is always safe to pass straight
+// through.
+func Err(err error) Field {
+	if err == nil {
+		return Field{Key: "error", Type: fieldString}
+	}
+	return Field{Key: "error", Type: fieldString, Str: err.Error()}
+}
+
+// Value returns the Field's payload as an interface{}, for sinks (like
+// JSONFormatter) that want the native type rather than rendered text.
+func (f Field) Value() interface{} {
+	switch f.Type {
+	case fieldInt:
+		return f.Int
+	case fieldDuration:
+		return time.Duration(f.Int)
+	case fieldString:
+		return f.Str
+	default:
+		return f.Iface
+	}
+}
+
+// text renders the Field the way the legacy key=value scheme does. A
+// Field with no Key (the odd-arg-out bare value pairsToFields produces)
+// renders as just its value, with no "=" — matching what the legacy
+// varargs path has always done for an unpaired trailing argument.
+func (f Field) text() string {
+	if f.Key == "" {
+		return f.valueText()
+	}
+	return fmt.Sprintf("%s=%s", f.Key, f.valueText())
+}
+
+// valueText renders just the Field's value, with no key or "=".
+func (f Field) valueText() string {
+	switch f.Type {
+	case fieldDuration:
+		return time.Duration(f.Int).String()
+	case fieldInt:
+		return strconv.FormatInt(f.Int, 10)
+	case fieldString:
+		return f.Str
+	default:
+		return fmt.Sprintf("%v", f.Iface)
+	}
+}
+
+// pairsToFields converts the legacy key/value varargs used by
+// Trace/Debug/Info/Warn/Error/Fatal into Fields, preserving the
+// odd-arg-out bare-value rendering the varargs path has always had.
+func pairsToFields(args []interface{}) []Field {
+	if len(args) == 0 {
+		return nil
+	}
+
+	fields := make([]Field, 0, (len(args)+1)/2)
+
+	num := len(args) / 2
+	for i := 0; i < num; i++ {
+		fields = append(fields, Field{Key: fmt.Sprintf("%v", args[i*2]), Type: fieldAny, Iface: args[i*2+1]})
+	}
+	if len(args)%2 == 1 {
+		fields = append(fields, Field{Type: fieldAny, Iface: args[len(args)-1]})
+	}
+
+	return fields
+}
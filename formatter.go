@@ -0,0 +1,106 @@
+// Copyright 2016 The kingshard Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package golog
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Formatter renders a structured Entry (one built via Infow/With) into
+// the bytes a sink should write.
+type Formatter interface {
+	Format(e Entry) []byte
+}
+
+// TextFormatter renders an Entry as `[module] "method" "msg" "k=v|k=v"`
+// (or, for a msg that was already run through printf, `[module]
+// "method" msg` with no trailing k/v tail) prefixed by the flag-driven
+// time/level/file text and followed by a backtrace if one is attached —
+// the same scheme output() has always used, so sinks that want plain
+// text keep looking the same whether callers use Info, Infow or Output.
+type TextFormatter struct{}
+
+func (TextFormatter) Format(e Entry) []byte {
+	var buf bytes.Buffer
+
+	buf.WriteString(e.Prefix)
+
+	if e.legacyPrintf {
+		fmt.Fprintf(&buf, `[%s] "%s" `, e.Module, e.Method)
+		buf.WriteString(e.Message)
+	} else {
+		fmt.Fprintf(&buf, `[%s] "%s" "%s" "`, e.Module, e.Method, e.Message)
+		for i, f := range e.Fields {
+			if i > 0 {
+				buf.WriteByte('|')
+			}
+			// '|' separates fields, so it is dropped from their text;
+			// nothing here goes through Sprintf, so '%' stays as is.
+			buf.WriteString(strings.Replace(f.text(), "|", "", -1))
+		}
+		buf.WriteByte('"')
+	}
+
+	if b := buf.Bytes(); len(b) == 0 || b[len(b)-1] != '\n' {
+		buf.WriteByte('\n')
+	}
+
+	if e.Backtrace != "" {
+		buf.WriteString(e.Backtrace)
+	}
+
+	return buf.Bytes()
+}
+
+// JSONFormatter renders an Entry as one JSON object per line:
+// {"ts":...,"level":"INFO","module":...,"file":"x.go:12","msg":...,"fields":{...}},
+// for ingestion by things like ELK or Loki.
+type JSONFormatter struct{}
+
+func (JSONFormatter) Format(e Entry) []byte {
+	rec := make(map[string]interface{}, 6)
+	rec["ts"] = e.Time.Format(TimeFormat)
+	rec["level"] = LevelName[e.Level]
+	rec["module"] = e.Module
+	rec["method"] = e.Method
+	rec["msg"] = e.Message
+
+	if e.File != "" {
+		rec["file"] = shortFileName(e.File) + ":" + strconv.Itoa(e.Line)
+	}
+
+	if len(e.Fields) > 0 {
+		fields := make(map[string]interface{}, len(e.Fields))
+		for _, f := range e.Fields {
+			fields[f.Key] = f.Value()
+		}
+		rec["fields"] = fields
+	}
+
+	if e.Backtrace != "" {
+		rec["backtrace"] = e.Backtrace
+	}
+
+	b, err := json.Marshal(rec)
+	if err != nil {
+		return []byte(fmt.Sprintf(`{"level":"ERROR","msg":"golog: json marshal failed: %s"}`+"\n", escape(err.Error(), false)))
+	}
+
+	return append(b, '\n')
+}
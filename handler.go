@@ -0,0 +1,57 @@
+// Copyright 2016 The kingshard Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package golog
+
+import "io"
+
+// Handler is a single log destination. It predates LogSink and only
+// takes a pre-rendered line, with no notion of level or formatting of
+// its own. New/NewDefault still accept a Handler and wrap it in a
+// FileSink internally; new sinks should implement LogSink instead.
+type Handler interface {
+	Write(b []byte) (n int, err error)
+	Flush() error
+	Sync() error
+	Close() error
+}
+
+// StreamHandler is a Handler that writes every line to an io.Writer,
+// e.g. os.Stdout or os.Stderr.
+type StreamHandler struct {
+	w io.Writer
+}
+
+// NewStreamHandler creates a StreamHandler that writes to w.
+func NewStreamHandler(w io.Writer) (*StreamHandler, error) {
+	return &StreamHandler{w: w}, nil
+}
+
+func (h *StreamHandler) Write(b []byte) (n int, err error) {
+	return h.w.Write(b)
+}
+
+// Flush is a no-op: StreamHandler never buffers.
+func (h *StreamHandler) Flush() error {
+	return nil
+}
+
+// Sync is a no-op: StreamHandler never buffers.
+func (h *StreamHandler) Sync() error {
+	return nil
+}
+
+func (h *StreamHandler) Close() error {
+	return nil
+}
@@ -55,16 +55,21 @@ type Logger struct {
 	level int
 	flag  int
 
-	handler Handler
+	sinks  []*sinkBinding
+	fields []Field
 
 	quit chan struct{}
-	msg  chan []byte
+	msg  chan Entry
 
 	bufs [][]byte
 
 	wg sync.WaitGroup
 
 	closed bool
+
+	//child is true for Loggers returned by With, which share their
+	//parent's quit channel and sinks rather than owning them
+	child bool
 }
 
 //new a logger with specified handler and flag
@@ -72,20 +77,23 @@ func New(handler Handler, flag int) *Logger {
 	var l = new(Logger)
 
 	l.level = LevelInfo
-	l.handler = handler
 
 	l.flag = flag
 
 	l.quit = make(chan struct{})
 	l.closed = false
 
-	l.msg = make(chan []byte, 1024)
+	l.msg = make(chan Entry, 1024)
 
 	l.bufs = make([][]byte, 0, 16)
 
 	l.wg.Add(1)
 	go l.run()
 
+	//preserve pre-sink behaviour: the handler sees everything the
+	//logger's own level lets through
+	l.AddSink(NewFileSink(handler), LevelTrace, TextFormatter{}, SinkErrorStderr)
+
 	return l
 }
 
@@ -121,9 +129,8 @@ func (l *Logger) run() {
 	defer l.wg.Done()
 	for {
 		select {
-		case msg := <-l.msg:
-			l.handler.Write(msg)
-			l.putBuf(msg)
+		case e := <-l.msg:
+			l.emit(e)
 		case <-l.quit:
 			if len(l.msg) == 0 {
 				return
@@ -155,8 +162,12 @@ func (l *Logger) putBuf(buf []byte) {
 	l.Unlock()
 }
 
+// Close shuts the logger down, draining any queued entries and
+// flushing/closing every sink. It is a no-op on a child Logger returned
+// by With, since those share their parent's quit channel and sinks;
+// close the root Logger instead.
 func (l *Logger) Close() {
-	if l.closed {
+	if l.closed || l.child {
 		return
 	}
 	l.closed = true
@@ -165,7 +176,10 @@ func (l *Logger) Close() {
 	l.wg.Wait()
 	l.quit = nil
 
-	l.handler.Close()
+	for _, b := range l.sinkSnapshot() {
+		b.sink.Flush()
+		b.sink.Close()
+	}
 }
 
 //set log level, any log level less than it will not log
@@ -186,9 +200,10 @@ func (l *Logger) Output(callDepth int, level int, format string, v ...interface{
 
 	buf := l.popBuf()
 
+	now := time.Now()
+
 	if l.flag&Ltime > 0 {
-		now := time.Now().Format(TimeFormat)
-		buf = append(buf, now...)
+		buf = append(buf, now.Format(TimeFormat)...)
 		buf = append(buf, " - "...)
 	}
 
@@ -197,21 +212,15 @@ func (l *Logger) Output(callDepth int, level int, format string, v ...interface{
 		buf = append(buf, " - "...)
 	}
 
-	if l.flag&Lfile > 0 {
-		_, file, line, ok := runtime.Caller(callDepth)
-		if !ok {
-			file = "???"
-			line = 0
-		} else {
-			for i := len(file) - 1; i > 0; i-- {
-				if file[i] == '/' {
-					file = file[i+1:]
-					break
-				}
-			}
-		}
+	pc, file, line, ok := runtime.Caller(callDepth)
+	if !ok {
+		file = "???"
+		line = 0
+	}
+	shortFile := shortFileName(file)
 
-		buf = append(buf, file...)
+	if l.flag&Lfile > 0 {
+		buf = append(buf, shortFile...)
 		buf = append(buf, ":["...)
 
 		buf = strconv.AppendInt(buf, int64(line), 10)
@@ -226,7 +235,206 @@ func (l *Logger) Output(callDepth int, level int, format string, v ...interface{
 		buf = append(buf, '\n')
 	}
 
-	l.msg <- buf
+	if shouldBacktrace(shortFile, line) {
+		buf = append(buf, Stack(0)...)
+	}
+
+	e := Entry{Time: now, Level: level, Message: string(buf), File: file, Line: line, PC: pc, rendered: true}
+	l.putBuf(buf)
+
+	l.msg <- e
+}
+
+// shortFileName trims file down to its base name, the same way Output
+// always has for display purposes.
+func shortFileName(file string) string {
+	for i := len(file) - 1; i > 0; i-- {
+		if file[i] == '/' {
+			return file[i+1:]
+		}
+	}
+	return file
+}
+
+// buildPrefix renders the flag-driven time/level/file prefix that
+// TextFormatter prepends to a rendered line.
+func (l *Logger) buildPrefix(now time.Time, level int, shortFile string, line int) string {
+	if l.flag == 0 {
+		return ""
+	}
+
+	var buf bytes.Buffer
+
+	if l.flag&Ltime > 0 {
+		buf.WriteString(now.Format(TimeFormat))
+		buf.WriteString(" - ")
+	}
+
+	if l.flag&Llevel > 0 {
+		buf.WriteString(LevelName[level])
+		buf.WriteString(" - ")
+	}
+
+	if l.flag&Lfile > 0 {
+		buf.WriteString(shortFile)
+		buf.WriteString(":[")
+		buf.WriteString(strconv.Itoa(line))
+		buf.WriteString("] - ")
+	}
+
+	return buf.String()
+}
+
+// outputModule builds and enqueues a module/method entry the way
+// output() always has, but leaves text-vs-JSON rendering to each
+// sink's Formatter instead of flattening eagerly, so sinks like
+// JSONFormatter see Module/Method/Fields instead of one opaque string.
+func (l *Logger) outputModule(callDepth int, level int, module string, method string, msg string, args []interface{}) {
+	if l.level > level {
+		return
+	}
+
+	now := time.Now()
+
+	_, file, line, ok := runtime.Caller(callDepth)
+	if !ok {
+		file = "???"
+		line = 0
+	}
+	shortFile := shortFileName(file)
+
+	e := Entry{
+		Time:   now,
+		Level:  level,
+		Module: module,
+		Method: method,
+		File:   file,
+		Line:   line,
+		Prefix: l.buildPrefix(now, level, shortFile, line),
+	}
+
+	if strings.Contains(msg, "%") {
+		e.legacyPrintf = true
+		e.Message = fmt.Sprintf(msg, args...)
+	} else {
+		e.Message = msg
+		e.Fields = pairsToFields(args)
+	}
+
+	if shouldBacktrace(shortFile, line) {
+		e.Backtrace = string(Stack(0))
+	}
+
+	l.msg <- e
+}
+
+// outputLiteral builds and enqueues an Entry whose Message is used
+// exactly as given, with no further formatting or k/v pairing — for
+// callers (the *Depthf variants) that already ran msg through
+// fmt.Sprintf themselves and would corrupt a literal '%' in the result
+// if outputModule's format-sniffing ran over it again.
+func (l *Logger) outputLiteral(callDepth int, level int, module string, method string, msg string) {
+	if l.level > level {
+		return
+	}
+
+	now := time.Now()
+
+	_, file, line, ok := runtime.Caller(callDepth)
+	if !ok {
+		file = "???"
+		line = 0
+	}
+	shortFile := shortFileName(file)
+
+	e := Entry{
+		Time:         now,
+		Level:        level,
+		Module:       module,
+		Method:       method,
+		Message:      msg,
+		File:         file,
+		Line:         line,
+		Prefix:       l.buildPrefix(now, level, shortFile, line),
+		legacyPrintf: true,
+	}
+
+	if shouldBacktrace(shortFile, line) {
+		e.Backtrace = string(Stack(0))
+	}
+
+	l.msg <- e
+}
+
+// With returns a child Logger that prepends fields to every Infow call
+// made on it, in addition to whatever's passed at the call site.
+// Children share the parent's sinks and message queue; Close on a
+// child is a no-op, so closing the root Logger is still the only way
+// to shut the whole thing down.
+func (l *Logger) With(fields ...Field) *Logger {
+	combined := make([]Field, 0, len(l.fields)+len(fields))
+	combined = append(combined, l.fields...)
+	combined = append(combined, fields...)
+
+	return &Logger{
+		level:  l.level,
+		flag:   l.flag,
+		sinks:  l.sinks,
+		fields: combined,
+		quit:   l.quit,
+		msg:    l.msg,
+		bufs:   make([][]byte, 0, 16),
+		child:  true,
+	}
+}
+
+// outputw builds and enqueues a structured Entry; unlike Output, its
+// rendering (text, JSON, ...) is deferred to each sink's Formatter.
+func (l *Logger) outputw(callDepth int, level int, module, method, msg string, fields ...Field) {
+	if l.level > level {
+		return
+	}
+
+	now := time.Now()
+
+	_, file, line, ok := runtime.Caller(callDepth)
+	if !ok {
+		file = "???"
+		line = 0
+	}
+	shortFile := shortFileName(file)
+
+	all := fields
+	if len(l.fields) > 0 {
+		all = make([]Field, 0, len(l.fields)+len(fields))
+		all = append(all, l.fields...)
+		all = append(all, fields...)
+	}
+
+	e := Entry{
+		Time:    now,
+		Level:   level,
+		Module:  module,
+		Method:  method,
+		Message: msg,
+		File:    file,
+		Line:    line,
+		Fields:  all,
+		Prefix:  l.buildPrefix(now, level, shortFile, line),
+	}
+
+	if shouldBacktrace(shortFile, line) {
+		e.Backtrace = string(Stack(0))
+	}
+
+	l.msg <- e
+}
+
+// Infow logs a structured entry with k/v Fields instead of printf or
+// key/value varargs; which bytes end up at a given sink depends on
+// that sink's Formatter (see TextFormatter, JSONFormatter).
+func (l *Logger) Infow(module, method, msg string, fields ...Field) {
+	l.outputw(2, LevelInfo, module, method, msg, fields...)
 }
 
 func (l *Logger) Write(p []byte) (n int, err error) {
@@ -275,31 +483,37 @@ func SetGoLoger(newLog *Logger, level string) {
 }
 
 func output(level int, module string, method string, msg string, args ...interface{}) {
+	// output() itself adds one more stack frame than InfoDepth/etc.
+	// calling outputDepth directly, so bump depth to keep the
+	// zero-depth call sites (Trace/Debug/Info/Warn/Error/Fatal)
+	// attributed to their real caller.
+	outputDepth(level, 1, module, method, msg, args...)
+}
+
+// outputDepth is output with an extra depth added to the fixed
+// callDepth passed to outputModule, so wrapper libraries can make
+// Output attribute the line to their own caller instead of to the
+// wrapper.
+func outputDepth(level int, depth int, module string, method string, msg string, args ...interface{}) {
 	if level < sysLogger.Level() {
 		return
 	}
-	//
-	var argsBuff bytes.Buffer
-	var content string
-	if strings.Contains(msg, "%") {
-		content = fmt.Sprintf(`[%s] "%s" `,
-			module, method) + fmt.Sprintf(msg, args...)
-	} else {
-		num := len(args) / 2
-		for i := 0; i < num; i++ {
-			argsBuff.WriteString(escape(fmt.Sprintf("%v=%v", args[i*2], args[i*2+1]), false))
-			if (i+1)*2 != len(args) {
-				argsBuff.WriteString("|")
-			}
-		}
-		if len(args)%2 == 1 {
-			argsBuff.WriteString(escape(fmt.Sprintf("%v", args[len(args)-1]), false))
-		}
-		content = fmt.Sprintf(`[%s] "%s" "%s" "%s"`,
-			module, method, msg, argsBuff.String())
+
+	sysLogger.outputModule(3+depth, level, module, method, msg, args)
+}
+
+// outputDepthf is outputDepth for the *Depthf variants, whose msg has
+// already been run through fmt.Sprintf by the caller — it must reach
+// sysLogger verbatim rather than going back through outputModule's
+// '%'-sniffing, or a literal '%' left in the formatted string (a
+// percentage, a URL-encoded value, ...) gets corrupted by a second,
+// argument-less Sprintf.
+func outputDepthf(level int, depth int, module string, method string, msg string) {
+	if level < sysLogger.Level() {
+		return
 	}
 
-	sysLogger.Output(3, level, content)
+	sysLogger.outputLiteral(3+depth, level, module, method, msg)
 }
 
 func Trace(module string, method string, msg string, args ...interface{}) {
@@ -317,8 +531,12 @@ func Warn(module string, method string, msg string, args ...interface{}) {
 func Error(module string, method string, msg string, args ...interface{}) {
 	output(LevelError, module, method, msg, args...)
 }
+// Fatal logs msg, then appends a dump of every goroutine's stack,
+// flushes every sink, and exits the process with status 255 — this is
+// for reporting a bug, as opposed to Exit's controlled shutdown.
 func Fatal(module string, method string, msg string, args ...interface{}) {
 	output(LevelFatal, module, method, msg, args...)
+	haltAndExit(255, true)
 }
 
 var (
@@ -0,0 +1,276 @@
+// Copyright 2016 The kingshard Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package golog
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/user"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RotateOptions controls how a RotatingFileHandler rolls its output
+// file over.
+type RotateOptions struct {
+	// Level is the LEVEL token baked into the filename and symlink,
+	// e.g. LevelInfo produces a "....log.INFO...." file and a
+	// "program.INFO" symlink.
+	Level int
+
+	// MaxSize rotates the current file once it would grow past
+	// MaxSize bytes. Zero disables size-based rotation.
+	MaxSize int64
+
+	// Daily rotates the current file on the first write after local
+	// midnight, independent of MaxSize.
+	Daily bool
+
+	// MaxBackups is how many old files to keep; once exceeded the
+	// oldest are deleted. Zero means unlimited.
+	MaxBackups int
+
+	// MaxAgeDays deletes files older than this many days regardless
+	// of MaxBackups. Zero means unlimited.
+	MaxAgeDays int
+
+	// CheckInterval is how often the retention sweep runs. Defaults
+	// to one hour.
+	CheckInterval time.Duration
+}
+
+// RotatingFileHandler is a Handler that writes to files named
+// program.host.user.log.LEVEL.YYYYMMDD-HHMMSS.pid under dir, maintains
+// a program.LEVEL symlink to the current file, rotates on size or day
+// boundaries, and prunes old files in the background. This mirrors
+// glog's glog_file.go.
+type RotatingFileHandler struct {
+	mu sync.Mutex
+
+	dir     string
+	program string
+	host    string
+	user    string
+	pid     int
+	opts    RotateOptions
+
+	f       *os.File
+	current string
+	size    int64
+	day     int
+
+	quit chan struct{}
+	wg   sync.WaitGroup
+}
+
+// NewRotatingFileHandler creates a RotatingFileHandler that writes
+// under dir using program as the filename prefix.
+func NewRotatingFileHandler(dir, program string, opts RotateOptions) (Handler, error) {
+	if opts.CheckInterval <= 0 {
+		opts.CheckInterval = time.Hour
+	}
+
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknownhost"
+	}
+
+	userName := "unknownuser"
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		userName = u.Username
+	}
+
+	h := &RotatingFileHandler{
+		dir:     dir,
+		program: program,
+		host:    host,
+		user:    userName,
+		pid:     os.Getpid(),
+		opts:    opts,
+		quit:    make(chan struct{}),
+	}
+
+	if err := h.rotate(time.Now()); err != nil {
+		return nil, err
+	}
+
+	h.wg.Add(1)
+	go h.cleanupLoop()
+
+	return h, nil
+}
+
+func (h *RotatingFileHandler) levelName() string {
+	if h.opts.Level >= 0 && h.opts.Level < len(LevelName) {
+		return LevelName[h.opts.Level]
+	}
+	return LevelName[LevelInfo]
+}
+
+func (h *RotatingFileHandler) filePrefix() string {
+	return fmt.Sprintf("%s.%s.%s.log.%s.", h.program, h.host, h.user, h.levelName())
+}
+
+func (h *RotatingFileHandler) fileName(now time.Time) string {
+	return fmt.Sprintf("%s%s.%d", h.filePrefix(), now.Format("20060102-150405"), h.pid)
+}
+
+func (h *RotatingFileHandler) symlinkName() string {
+	return fmt.Sprintf("%s.%s", h.program, h.levelName())
+}
+
+// rotate closes the current file, if any, opens a fresh one, and
+// repoints the program.LEVEL symlink at it. Caller must hold h.mu.
+func (h *RotatingFileHandler) rotate(now time.Time) error {
+	if h.f != nil {
+		h.f.Close()
+	}
+
+	name := h.fileName(now)
+	path := filepath.Join(h.dir, name)
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+
+	h.f = f
+	h.current = name
+	h.size = 0
+	h.day = now.YearDay()
+
+	symlink := filepath.Join(h.dir, h.symlinkName())
+	os.Remove(symlink)
+	os.Symlink(name, symlink)
+
+	return nil
+}
+
+func (h *RotatingFileHandler) Write(b []byte) (int, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	now := time.Now()
+	if (h.opts.MaxSize > 0 && h.size+int64(len(b)) > h.opts.MaxSize) ||
+		(h.opts.Daily && now.YearDay() != h.day) {
+		if err := h.rotate(now); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := h.f.Write(b)
+	h.size += int64(n)
+
+	return n, err
+}
+
+// Flush commits buffered data; RotatingFileHandler writes unbuffered
+// so this is equivalent to Sync.
+func (h *RotatingFileHandler) Flush() error {
+	return h.Sync()
+}
+
+// Sync flushes the current file to stable storage.
+func (h *RotatingFileHandler) Sync() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.f == nil {
+		return nil
+	}
+	return h.f.Sync()
+}
+
+func (h *RotatingFileHandler) Close() error {
+	close(h.quit)
+	h.wg.Wait()
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.f == nil {
+		return nil
+	}
+	return h.f.Close()
+}
+
+func (h *RotatingFileHandler) cleanupLoop() {
+	defer h.wg.Done()
+
+	ticker := time.NewTicker(h.opts.CheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			h.cleanup()
+		case <-h.quit:
+			return
+		}
+	}
+}
+
+// cleanup enforces MaxBackups and MaxAgeDays against the files this
+// handler owns, always excluding the file currently open for writing
+// regardless of its mtime rank — deleting it out from under h.f would
+// unlink the active log (leaving the program.LEVEL symlink dangling
+// and every subsequent write going into an orphaned inode) without
+// Write ever returning an error.
+func (h *RotatingFileHandler) cleanup() {
+	if h.opts.MaxBackups <= 0 && h.opts.MaxAgeDays <= 0 {
+		return
+	}
+
+	prefix := h.filePrefix()
+
+	h.mu.Lock()
+	current := h.current
+	h.mu.Unlock()
+
+	infos, err := ioutil.ReadDir(h.dir)
+	if err != nil {
+		return
+	}
+
+	type backup struct {
+		name    string
+		modTime time.Time
+	}
+
+	var backups []backup
+	for _, info := range infos {
+		if info.IsDir() || !strings.HasPrefix(info.Name(), prefix) || info.Name() == current {
+			continue
+		}
+		backups = append(backups, backup{name: info.Name(), modTime: info.ModTime()})
+	}
+
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[i].modTime.After(backups[j].modTime)
+	})
+
+	now := time.Now()
+	for i, b := range backups {
+		tooOld := h.opts.MaxAgeDays > 0 && now.Sub(b.modTime) > time.Duration(h.opts.MaxAgeDays)*24*time.Hour
+		tooMany := h.opts.MaxBackups > 0 && i >= h.opts.MaxBackups
+		if tooOld || tooMany {
+			os.Remove(filepath.Join(h.dir, b.name))
+		}
+	}
+}
@@ -0,0 +1,317 @@
+// Copyright 2016 The kingshard Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package golog
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// Entry is the fully-assembled representation of one log call, handed to
+// every LogSink. Trace/Debug/Info/Warn/Error/Fatal (and their Depth and
+// Infow/With counterparts) all populate Module/Method/Fields and leave
+// rendering (text, JSON, ...) to each sink's Formatter; only the
+// generic low-level Logger.Output, which has no module/method to offer
+// a Formatter, renders eagerly and sets rendered so sinks use its
+// Message as-is.
+type Entry struct {
+	Time    time.Time
+	Level   int
+	Module  string
+	Method  string
+	Message string
+	File    string
+	Line    int
+	PC      uintptr
+	Fields  []Field
+
+	// Prefix is the flag-driven time/level/file text TextFormatter
+	// prepends to the rendered line; JSONFormatter ignores it since it
+	// has Time/Level/File as proper fields already.
+	Prefix string
+
+	// Backtrace, if non-empty, is a stack dump to attach to the line
+	// (see SetBacktraceAt).
+	Backtrace string
+
+	// legacyPrintf is true when Message came from a msg string
+	// containing '%', i.e. it was built with fmt.Sprintf(msg, args...)
+	// rather than paired into Fields, and for every entry built by
+	// outputLiteral, whose msg the caller already formatted;
+	// TextFormatter renders it without the quoting/Fields tail used for
+	// the common case, matching what
+	// output() has always done.
+	legacyPrintf bool
+
+	rendered bool
+}
+
+// LogSink receives log entries and is responsible for getting them
+// somewhere: a file, syslog, stderr, another sink entirely. A Logger
+// fans each log call out to every sink whose level it clears, so one
+// call can, say, land in a rotating file at INFO and on stderr at
+// ERROR simultaneously.
+type LogSink interface {
+	Emit(e Entry) error
+	Flush() error
+	Close() error
+}
+
+// SinkErrorPolicy controls what a Logger does when a LogSink.Emit call
+// returns an error.
+type SinkErrorPolicy int
+
+const (
+	// SinkErrorIgnore silently drops the error.
+	SinkErrorIgnore SinkErrorPolicy = iota
+	// SinkErrorStderr reports the error to os.Stderr and continues.
+	SinkErrorStderr
+)
+
+// sinkBinding pairs a LogSink with the minimum level it should receive,
+// the Formatter used to render entries that aren't already rendered,
+// and what to do if it errors.
+type sinkBinding struct {
+	sink      LogSink
+	level     int
+	formatter Formatter
+	onError   SinkErrorPolicy
+}
+
+// FileSink adapts a Handler into a LogSink, so any Handler — a plain
+// StreamHandler, a RotatingFileHandler — can be added to a Logger
+// alongside other sinks. This is what lets a single log call land in a
+// rotating file at one level and on stderr at another: wrap the
+// *RotatingFileHandler in a FileSink and AddSink it next to a
+// StderrSink with its own level and formatter.
+type FileSink struct {
+	handler Handler
+}
+
+// NewFileSink wraps handler as a LogSink.
+func NewFileSink(handler Handler) *FileSink {
+	return &FileSink{handler: handler}
+}
+
+func (f *FileSink) Emit(e Entry) error {
+	_, err := f.handler.Write([]byte(e.Message))
+	return err
+}
+
+func (f *FileSink) Flush() error { return f.handler.Flush() }
+
+func (f *FileSink) Close() error { return f.handler.Close() }
+
+// AddSink appends sink to the logger's fan-out list. Entries at or
+// above level are delivered to it; formatter renders entries that
+// arrive unrendered (every entry not built via Logger.Output) and
+// defaults to TextFormatter{} if nil. onError controls what happens if
+// Emit fails. Safe to call while the logger is in use.
+func (l *Logger) AddSink(sink LogSink, level int, formatter Formatter, onError SinkErrorPolicy) {
+	if formatter == nil {
+		formatter = TextFormatter{}
+	}
+
+	l.Lock()
+	l.sinks = append(l.sinks, &sinkBinding{sink: sink, level: level, formatter: formatter, onError: onError})
+	l.Unlock()
+}
+
+// RemoveSink removes sink from the logger's fan-out list, reporting
+// whether it was found.
+func (l *Logger) RemoveSink(sink LogSink) bool {
+	l.Lock()
+	defer l.Unlock()
+
+	for i, b := range l.sinks {
+		if b.sink == sink {
+			l.sinks = append(l.sinks[:i], l.sinks[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+func (l *Logger) sinkSnapshot() []*sinkBinding {
+	l.Lock()
+	snapshot := make([]*sinkBinding, len(l.sinks))
+	copy(snapshot, l.sinks)
+	l.Unlock()
+
+	return snapshot
+}
+
+// emit fans e out to every sink that clears its configured level,
+// rendering it through that sink's Formatter first unless e already
+// arrived fully rendered (only Logger.Output renders eagerly).
+func (l *Logger) emit(e Entry) {
+	for _, b := range l.sinkSnapshot() {
+		if e.Level < b.level {
+			continue
+		}
+
+		out := e
+		if !out.rendered {
+			out.Message = string(b.formatter.Format(out))
+		}
+
+		if err := b.sink.Emit(out); err != nil && b.onError == SinkErrorStderr {
+			fmt.Fprintf(os.Stderr, "golog: sink error: %v\n", err)
+		}
+	}
+}
+
+// StderrSink writes every entry's rendered message to os.Stderr.
+type StderrSink struct{}
+
+// NewStderrSink creates a StderrSink.
+func NewStderrSink() *StderrSink {
+	return &StderrSink{}
+}
+
+func (s *StderrSink) Emit(e Entry) error {
+	_, err := fmt.Fprint(os.Stderr, e.Message)
+	return err
+}
+
+func (s *StderrSink) Flush() error { return nil }
+
+func (s *StderrSink) Close() error { return nil }
+
+// TeeSink fans Emit/Flush/Close out to every child sink and returns the
+// first error encountered, after still calling the rest.
+type TeeSink struct {
+	sinks []LogSink
+}
+
+// NewTeeSink creates a TeeSink that forwards to every sink in sinks.
+func NewTeeSink(sinks ...LogSink) *TeeSink {
+	return &TeeSink{sinks: sinks}
+}
+
+func (t *TeeSink) Emit(e Entry) error {
+	var first error
+	for _, s := range t.sinks {
+		if err := s.Emit(e); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+func (t *TeeSink) Flush() error {
+	var first error
+	for _, s := range t.sinks {
+		if err := s.Flush(); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+func (t *TeeSink) Close() error {
+	var first error
+	for _, s := range t.sinks {
+		if err := s.Close(); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+// AsyncSink wraps another LogSink with the same channel-based buffering
+// Logger itself has always used, so a slow sink (a syslog connection, a
+// network appender) can be decoupled from the caller's goroutine.
+type AsyncSink struct {
+	next LogSink
+
+	// entries carries both real log entries and flush barriers, in a
+	// single channel so FIFO order guarantees a barrier is only
+	// processed after every entry queued ahead of it — see Flush.
+	entries chan asyncOp
+	quit    chan struct{}
+	wg      sync.WaitGroup
+}
+
+// asyncOp is either a log entry to deliver to next, or (when done is
+// non-nil) a flush barrier to close once every prior entry has drained.
+type asyncOp struct {
+	entry Entry
+	done  chan struct{}
+}
+
+// NewAsyncSink wraps next so Emit enqueues onto a buffered channel of
+// size bufSize instead of blocking on next directly.
+func NewAsyncSink(next LogSink, bufSize int) *AsyncSink {
+	a := &AsyncSink{
+		next:    next,
+		entries: make(chan asyncOp, bufSize),
+		quit:    make(chan struct{}),
+	}
+
+	a.wg.Add(1)
+	go a.run()
+
+	return a
+}
+
+func (a *AsyncSink) run() {
+	defer a.wg.Done()
+	for {
+		select {
+		case op := <-a.entries:
+			a.handle(op)
+		case <-a.quit:
+			for len(a.entries) > 0 {
+				a.handle(<-a.entries)
+			}
+			return
+		}
+	}
+}
+
+func (a *AsyncSink) handle(op asyncOp) {
+	if op.done != nil {
+		close(op.done)
+		return
+	}
+	a.next.Emit(op.entry)
+}
+
+func (a *AsyncSink) Emit(e Entry) error {
+	a.entries <- asyncOp{entry: e}
+	return nil
+}
+
+// Flush waits for every entry queued ahead of this call to reach next
+// before delegating to next.Flush, so callers relying on Flush (notably
+// Fatal's flush-before-exit path) see a queue that is actually empty
+// rather than racing the run goroutine.
+func (a *AsyncSink) Flush() error {
+	done := make(chan struct{})
+	a.entries <- asyncOp{done: done}
+	<-done
+
+	return a.next.Flush()
+}
+
+func (a *AsyncSink) Close() error {
+	close(a.quit)
+	a.wg.Wait()
+
+	return a.next.Close()
+}
@@ -0,0 +1,57 @@
+// Copyright 2016 The kingshard Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+// +build !windows
+
+package golog
+
+import "log/syslog"
+
+// SyslogSink writes every entry's rendered message to a local or
+// remote syslog daemon via the standard library's log/syslog, which
+// this package isn't available on Windows.
+type SyslogSink struct {
+	w *syslog.Writer
+}
+
+// NewSyslogSink dials syslog the same way syslog.Dial does: network
+// and raddr empty connects to the local syslog daemon. priority sets
+// the default facility/severity for the connection; tag is the
+// program name syslog records the message under.
+func NewSyslogSink(network, raddr string, priority syslog.Priority, tag string) (*SyslogSink, error) {
+	w, err := syslog.Dial(network, raddr, priority, tag)
+	if err != nil {
+		return nil, err
+	}
+	return &SyslogSink{w: w}, nil
+}
+
+func (s *SyslogSink) Emit(e Entry) error {
+	switch {
+	case e.Level >= LevelFatal:
+		return s.w.Crit(e.Message)
+	case e.Level >= LevelError:
+		return s.w.Err(e.Message)
+	case e.Level >= LevelWarn:
+		return s.w.Warning(e.Message)
+	case e.Level >= LevelInfo:
+		return s.w.Info(e.Message)
+	default:
+		return s.w.Debug(e.Message)
+	}
+}
+
+func (s *SyslogSink) Flush() error { return nil }
+
+func (s *SyslogSink) Close() error { return s.w.Close() }